@@ -0,0 +1,56 @@
+package customerimporter
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// generateBenchmarkCSV writes a synthetic CSV with the given number of rows
+// spread across 1000 distinct domains, for benchmarking GetDomainCounts at
+// the "could be 1m lines" scale called out in the package comment.
+func generateBenchmarkCSV(b *testing.B, rows int) string {
+	b.Helper()
+
+	file, err := os.CreateTemp("", "bench_customers_*.csv")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString("first_name,last_name,email,gender,ip_address\n"); err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < rows; i++ {
+		if _, err := fmt.Fprintf(file, "First%d,Last%d,user%d@domain%d.com,Female,10.0.%d.%d\n", i, i, i, i%1000, (i/255)%255, i%255); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return file.Name()
+}
+
+// BenchmarkGetDomainCounts measures GetDomainCounts' consumer pool scaling
+// on a synthetic 1M-row input.
+func BenchmarkGetDomainCounts(b *testing.B) {
+	path := generateBenchmarkCSV(b, 1_000_000)
+	defer os.Remove(path)
+
+	for _, workers := range []int{1, 2, 4, 8, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			importer, err := NewCustomerImporter("email", WithFile(path))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := importer.getDomainCounts(workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
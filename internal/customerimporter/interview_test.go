@@ -7,28 +7,39 @@ import (
 )
 
 func TestNewCustomerImporter(t *testing.T) {
-	_, err := NewCustomerImporter("customers_test.csv", "email")
+	path := writeTempCSV(t, "customers_test*.csv",
+		"first_name,last_name,email,gender,ip_address\n"+
+			"Mildred,Hernandez,bortiz2@example.com,Female,38.194.51.128\n")
+	defer os.Remove(path)
+
+	_, err := NewCustomerImporter("email", WithFile(path))
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
 	// Test invalid file path
-	_, err = NewCustomerImporter("invalid.txt", "email")
+	_, err = NewCustomerImporter("email", WithFile("invalid.txt"))
 	if err == nil {
 		t.Error("Expected error for invalid file path, got nil")
 	}
 
 	// Test non-existent file
-	_, err = NewCustomerImporter("nonexistent.csv", "email")
+	_, err = NewCustomerImporter("email", WithFile("nonexistent.csv"))
 	if err == nil {
 		t.Error("Expected error for non-existent file, got nil")
 	}
 
 	// Test empty email field name
-	_, err = NewCustomerImporter("customers_test.csv", "")
+	_, err = NewCustomerImporter("", WithFile(path))
 	if err == nil {
 		t.Error("Expected error for empty email field name, got nil")
 	}
+
+	// Test no source configured
+	_, err = NewCustomerImporter("email")
+	if err == nil {
+		t.Error("Expected error for no input source, got nil")
+	}
 }
 
 func TestGetDomainInformation_FileOpenError(t *testing.T) {
@@ -112,7 +123,7 @@ func TestGetDomainInformation_OnlyHeader(t *testing.T) {
 	defer os.Remove(file.Name())
 	file.Close()
 
-	importer, err := NewCustomerImporter(file.Name(), "email")
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -149,7 +160,7 @@ func TestGetDomainInformation_InvalidEmails(t *testing.T) {
 	}
 	file.Close()
 
-	importer, err := NewCustomerImporter(file.Name(), "email")
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -187,7 +198,7 @@ func TestGetDomainInformation(t *testing.T) {
 	}
 	file.Close()
 
-	importer, err := NewCustomerImporter(file.Name(), "email")
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
 	if err != nil {
 		t.Fatal(err)
 	}
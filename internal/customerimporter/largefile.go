@@ -0,0 +1,407 @@
+package customerimporter
+
+// LargeFileMode is an external merge-sort alternative to GetDomainCounts for
+// inputs too big to hold as a single map[string]int in memory (the header
+// comment on this package explicitly flags that concern for 1M+ line
+// inputs on a small machine). Domains are hashed into on-disk shards,
+// counted one small shard at a time, and the sorted per-shard runs are
+// k-way merged into the final, fully sorted result. Memory usage becomes
+// O(shard size) rather than O(unique domains).
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LargeFileMode configures an external merge-sort pass over a CSV file.
+type LargeFileMode struct {
+	// ShardCount is how many on-disk shards domains are hashed into.
+	// Larger values trade more disk I/O for less memory per shard.
+	ShardCount int
+	// TempDir is where shard and run files are created; the OS default
+	// temp directory is used when empty.
+	TempDir string
+}
+
+// NewLargeFileMode creates a LargeFileMode with shardCount on-disk shards
+// under tempDir. A non-positive shardCount falls back to 16.
+func NewLargeFileMode(shardCount int, tempDir string) *LargeFileMode {
+	if shardCount <= 0 {
+		shardCount = 16
+	}
+
+	return &LargeFileMode{ShardCount: shardCount, TempDir: tempDir}
+}
+
+// normalizeLargeFileMode fills in a usable ShardCount for a nil mode or a
+// zero-value LargeFileMode{} constructed directly rather than through
+// NewLargeFileMode, so shardIndex's modulo can never see a zero shardCount.
+func normalizeLargeFileMode(mode *LargeFileMode) *LargeFileMode {
+	if mode == nil {
+		return NewLargeFileMode(0, "")
+	}
+	if mode.ShardCount <= 0 {
+		normalized := *mode
+		normalized.ShardCount = 16
+		return &normalized
+	}
+
+	return mode
+}
+
+// GetDomainCountsLarge is the LargeFileMode equivalent of GetDomainCounts:
+// it materializes the full, sorted result but never holds more than one
+// shard's worth of counts in memory at a time.
+func (importer *customerImporter) GetDomainCountsLarge(mode *LargeFileMode) ([]emailDomain, error) {
+	iterator, err := importer.StreamDomainCountsLarge(mode)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	var result []emailDomain
+	for {
+		domain, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		result = append(result, domain)
+	}
+
+	return result, iterator.Err()
+}
+
+// StreamDomainCountsLarge is the streaming counterpart of
+// GetDomainCountsLarge, for callers who cannot hold the whole []emailDomain
+// result in memory either. The returned iterator must be closed to release
+// its on-disk run files.
+func (importer *customerImporter) StreamDomainCountsLarge(mode *LargeFileMode) (*DomainCountIterator, error) {
+	mode = normalizeLargeFileMode(mode)
+
+	shardPaths, err := importer.shardDomains(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	runPaths := make([]string, 0, len(shardPaths))
+	for i, shardPath := range shardPaths {
+		runPath, err := sortShard(shardPath, mode.TempDir)
+		if err != nil {
+			// sortShard has already removed shardPath itself; clean up the
+			// runs already produced and the shards not yet reached.
+			removeFiles(runPaths)
+			removeFiles(shardPaths[i+1:])
+			return nil, err
+		}
+		runPaths = append(runPaths, runPath)
+	}
+
+	iterator := &DomainCountIterator{
+		scanners: make([]*bufio.Scanner, len(runPaths)),
+		files:    make([]*os.File, len(runPaths)),
+	}
+
+	for i, path := range runPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			iterator.Close()
+			removeFiles(runPaths[i+1:])
+			return nil, fmt.Errorf("failed to open run file %s: %w", path, err)
+		}
+		iterator.files[i] = f
+		iterator.scanners[i] = bufio.NewScanner(f)
+		iterator.advance(i)
+	}
+
+	return iterator, nil
+}
+
+// removeFiles best-effort removes every path in paths, used to clean up
+// on-disk shard/run files left behind by an error partway through
+// StreamDomainCountsLarge.
+func removeFiles(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
+
+// shardDomains streams the CSV file once, hashing each valid email's domain
+// into one of mode.ShardCount on-disk shard files so later phases only ever
+// need to hold one shard's domains in memory.
+func (importer *customerImporter) shardDomains(mode *LargeFileMode) (paths []string, err error) {
+	source, err := importer.openSource()
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	reader := csv.NewReader(source)
+
+	fileHeaders, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the headers row from the CSV file: %w", err)
+	}
+
+	emailIndex := indexOf(fileHeaders, importer.emailFieldName)
+	if emailIndex == -1 {
+		return nil, fmt.Errorf("failed to find the field '%s' in the headers", importer.emailFieldName)
+	}
+
+	shardFiles := make([]*os.File, mode.ShardCount)
+	shardWriters := make([]*bufio.Writer, mode.ShardCount)
+	defer closeShardFiles(shardFiles)
+	defer func() {
+		// On any error, the shard files produced so far are useless and
+		// would otherwise be leaked on disk.
+		if err != nil {
+			for _, f := range shardFiles {
+				if f != nil {
+					os.Remove(f.Name())
+				}
+			}
+		}
+	}()
+
+	for i := range shardFiles {
+		f, err := os.CreateTemp(mode.TempDir, fmt.Sprintf("domain_shard_%d_*.txt", i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shard file: %w", err)
+		}
+		shardFiles[i] = f
+		shardWriters[i] = bufio.NewWriter(f)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		email := record[emailIndex]
+		if !isValidEmail(email) {
+			log.Printf("invalid email address found in row %v, skipping", record)
+			continue
+		}
+
+		domain, err := extractEmailDomain(email)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		shard := shardIndex(domain, mode.ShardCount)
+		if _, err := shardWriters[shard].WriteString(domain + "\n"); err != nil {
+			return nil, fmt.Errorf("failed to write to shard file: %w", err)
+		}
+	}
+
+	paths = make([]string, mode.ShardCount)
+	for i, w := range shardWriters {
+		if err := w.Flush(); err != nil {
+			return nil, fmt.Errorf("failed to flush shard file: %w", err)
+		}
+		paths[i] = shardFiles[i].Name()
+	}
+
+	return paths, nil
+}
+
+// sortShard counts the domain occurrences in shardPath (small enough to fit
+// in memory by construction) and writes them out as a "count,domain" run
+// file, already sorted by count desc, domain asc. shardPath is always
+// removed before returning, whether or not sorting succeeded.
+func sortShard(shardPath, tempDir string) (string, error) {
+	defer os.Remove(shardPath)
+
+	file, err := os.Open(shardPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open shard file %s: %w", shardPath, err)
+	}
+
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		counts[scanner.Text()]++
+	}
+	scanErr := scanner.Err()
+	file.Close()
+
+	if scanErr != nil {
+		return "", fmt.Errorf("failed to read shard file %s: %w", shardPath, scanErr)
+	}
+
+	sorted := make([]emailDomain, 0, len(counts))
+	for domain, count := range counts {
+		sorted = append(sorted, emailDomain{Domain: domain, CustomerCount: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CustomerCount != sorted[j].CustomerCount {
+			return sorted[i].CustomerCount > sorted[j].CustomerCount
+		}
+		return sorted[i].Domain < sorted[j].Domain
+	})
+
+	runFile, err := os.CreateTemp(tempDir, "domain_run_*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create run file: %w", err)
+	}
+	runPath := runFile.Name()
+
+	writer := bufio.NewWriter(runFile)
+	for _, d := range sorted {
+		if _, err := fmt.Fprintf(writer, "%d,%s\n", d.CustomerCount, d.Domain); err != nil {
+			runFile.Close()
+			os.Remove(runPath)
+			return "", fmt.Errorf("failed to write run file: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		runFile.Close()
+		os.Remove(runPath)
+		return "", fmt.Errorf("failed to flush run file: %w", err)
+	}
+	if err := runFile.Close(); err != nil {
+		os.Remove(runPath)
+		return "", fmt.Errorf("failed to close run file: %w", err)
+	}
+
+	return runPath, nil
+}
+
+// shardIndex deterministically maps domain to one of shardCount shards.
+func shardIndex(domain string, shardCount int) int {
+	h := fnv.New32a()
+	h.Write([]byte(domain))
+	return int(h.Sum32() % uint32(shardCount))
+}
+
+func closeShardFiles(files []*os.File) {
+	for _, f := range files {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
+// runItem is one "count,domain" line read off a sorted shard run, ordered
+// for the k-way merge below by count desc like sortEmailDomainsByCount,
+// with ties additionally broken by domain asc.
+type runItem struct {
+	domain string
+	count  int
+	index  int
+}
+
+// runHeap is a min/max-heap of runItem ordered by count desc, domain asc,
+// so Pop always yields the next entry in the final merged order.
+type runHeap []runItem
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count > h[j].count
+	}
+	return h[i].domain < h[j].domain
+}
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)   { *h = append(*h, x.(runItem)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// DomainCountIterator streams the k-way merged output of LargeFileMode one
+// domain at a time, so a caller never has to hold the full result in
+// memory either.
+type DomainCountIterator struct {
+	scanners []*bufio.Scanner
+	files    []*os.File
+	heap     runHeap
+	err      error
+}
+
+// Next returns the next emailDomain in sorted (count desc, domain asc)
+// order, or ok=false once every run has been exhausted.
+func (it *DomainCountIterator) Next() (emailDomain, bool) {
+	if it.heap.Len() == 0 {
+		return emailDomain{}, false
+	}
+
+	top := heap.Pop(&it.heap).(runItem)
+	it.advance(top.index)
+
+	return emailDomain{Domain: top.domain, CustomerCount: top.count}, true
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (it *DomainCountIterator) Err() error {
+	return it.err
+}
+
+// Close releases the iterator's underlying run files.
+func (it *DomainCountIterator) Close() error {
+	var firstErr error
+	for _, f := range it.files {
+		if f == nil {
+			continue
+		}
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		os.Remove(f.Name())
+	}
+	return firstErr
+}
+
+// advance reads the next line of run index and pushes it onto the heap.
+func (it *DomainCountIterator) advance(index int) {
+	scanner := it.scanners[index]
+	if scanner == nil || !scanner.Scan() {
+		if scanner != nil {
+			if err := scanner.Err(); err != nil && it.err == nil {
+				it.err = err
+			}
+		}
+		return
+	}
+
+	item, err := parseRunLine(scanner.Text(), index)
+	if err != nil {
+		if it.err == nil {
+			it.err = err
+		}
+		return
+	}
+
+	heap.Push(&it.heap, item)
+}
+
+func parseRunLine(line string, index int) (runItem, error) {
+	parts := strings.SplitN(line, ",", 2)
+	if len(parts) != 2 {
+		return runItem{}, fmt.Errorf("malformed run line: %q", line)
+	}
+
+	count, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return runItem{}, fmt.Errorf("malformed run line: %q", line)
+	}
+
+	return runItem{domain: parts[1], count: count, index: index}, nil
+}
@@ -0,0 +1,85 @@
+package customerimporter
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestGetDomainCountsLarge(t *testing.T) {
+	file, err := os.CreateTemp("", "large_mode*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	contents := "first_name,email\n"
+	domains := []string{"a.com", "b.com", "c.com"}
+	counts := map[string]int{"a.com": 5, "b.com": 3, "c.com": 1}
+	for _, domain := range domains {
+		for i := 0; i < counts[domain]; i++ {
+			contents += fmt.Sprintf("x,user%d@%s\n", i, domain)
+		}
+	}
+	if _, err := file.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := importer.GetDomainCountsLarge(NewLargeFileMode(4, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []emailDomain{
+		{"a.com", 5},
+		{"b.com", 3},
+		{"c.com", 1},
+	}
+	if !isEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamDomainCountsLarge_Close(t *testing.T) {
+	file, err := os.CreateTemp("", "large_mode_stream*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString("first_name,email\nA,a@example.com\nB,b@example.com\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := importer.StreamDomainCountsLarge(NewLargeFileMode(2, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain, ok := iterator.Next()
+	if !ok || domain.Domain != "example.com" || domain.CustomerCount != 2 {
+		t.Errorf("expected example.com with count 2, got %v, ok=%v", domain, ok)
+	}
+
+	if _, ok := iterator.Next(); ok {
+		t.Error("expected no further results")
+	}
+	if err := iterator.Err(); err != nil {
+		t.Errorf("expected no iteration error, got %v", err)
+	}
+	if err := iterator.Close(); err != nil {
+		t.Errorf("expected no close error, got %v", err)
+	}
+}
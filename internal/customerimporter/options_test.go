@@ -0,0 +1,152 @@
+package customerimporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWithReader(t *testing.T) {
+	csv := "first_name,email\nA,a@example.com\nB,b@example.com\n"
+
+	importer, err := NewCustomerImporter("email", WithReader(strings.NewReader(csv)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domainInfo, err := importer.GetDomainCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []emailDomain{{"example.com", 2}}
+	if !isEqual(domainInfo, expected) {
+		t.Errorf("expected %v, got %v", expected, domainInfo)
+	}
+}
+
+func TestWithGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("first_name,email\nA,a@example.com\n"))
+	gz.Close()
+
+	importer, err := NewCustomerImporter("email", WithReader(&buf), WithGzip())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domainInfo, err := importer.GetDomainCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []emailDomain{{"example.com", 1}}
+	if !isEqual(domainInfo, expected) {
+		t.Errorf("expected %v, got %v", expected, domainInfo)
+	}
+}
+
+func TestWithURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first_name,email\nA,a@example.com\nB,b@example.com\n"))
+	}))
+	defer server.Close()
+
+	importer, err := NewCustomerImporter("email", WithURL(context.Background(), server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domainInfo, err := importer.GetDomainCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []emailDomain{{"example.com", 2}}
+	if !isEqual(domainInfo, expected) {
+		t.Errorf("expected %v, got %v", expected, domainInfo)
+	}
+
+	// The response should be cached to a temp file so the source can be
+	// re-read without hitting the server again.
+	if importer.csvFilePath == "" {
+		t.Error("expected csvFilePath to be set after fetching a URL source")
+	}
+	if _, err := os.Stat(importer.csvFilePath); err != nil {
+		t.Errorf("expected cached file to exist: %v", err)
+	}
+	os.Remove(importer.csvFilePath)
+}
+
+// TestWithURL_TransparentGzipResponse exercises a server that gzips its
+// response over the wire (the request sets no Accept-Encoding, so
+// net/http's transport decodes this itself, stripping Content-Encoding
+// before downloadToTempFile ever sees the body).
+func TestWithURL_TransparentGzipResponse(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("first_name,email\nA,a@example.com\n"))
+	gz.Close()
+	body := buf.Bytes()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	importer, err := NewCustomerImporter("email", WithURL(context.Background(), server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domainInfo, err := importer.GetDomainCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []emailDomain{{"example.com", 1}}
+	if !isEqual(domainInfo, expected) {
+		t.Errorf("expected %v, got %v", expected, domainInfo)
+	}
+	os.Remove(importer.csvFilePath)
+}
+
+// TestWithURL_WithGzipBody covers a source that is gzipped at the body
+// level (e.g. a "customers.csv.gz" download): the server sends no
+// Content-Encoding, so net/http passes the gzip bytes straight through,
+// and WithGzip decodes them once cached to a temp file.
+func TestWithURL_WithGzipBody(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("first_name,email\nA,a@example.com\nB,b@example.com\n"))
+	gz.Close()
+	body := buf.Bytes()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	importer, err := NewCustomerImporter("email", WithURL(context.Background(), server.URL), WithGzip())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domainInfo, err := importer.GetDomainCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []emailDomain{{"example.com", 2}}
+	if !isEqual(domainInfo, expected) {
+		t.Errorf("expected %v, got %v", expected, domainInfo)
+	}
+	os.Remove(importer.csvFilePath)
+}
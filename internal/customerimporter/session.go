@@ -0,0 +1,334 @@
+package customerimporter
+
+// Importer is a stateful, single-flight import session layered on top of the
+// domain-counting pipeline in this package. Unlike customerImporter's
+// one-shot GetDomainCounts, it is meant to be started, observed and
+// cancelled by a caller (e.g. an HTTP handler) while a large ZIP/CSV upload
+// is still streaming in.
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ImportState describes the lifecycle of an Importer's current (or most
+// recent) run.
+type ImportState string
+
+const (
+	StateNone      ImportState = "none"
+	StateImporting ImportState = "importing"
+	StateStopping  ImportState = "stopping"
+	StateFinished  ImportState = "finished"
+	StateFailed    ImportState = "failed"
+)
+
+// ImportStatus is a point-in-time snapshot of an Importer's progress.
+type ImportStatus struct {
+	State      ImportState
+	Processed  int
+	Failed     int
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Importer runs a single import at a time over a CSV file or a ZIP archive
+// of CSV files, feeding every row through the same validation and
+// domain-counting logic as customerImporter. It is safe for concurrent use.
+type Importer struct {
+	emailFieldName string
+	queueSize      int
+
+	mu     sync.Mutex
+	status ImportStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// processed and failed are updated from inside the row callback, so a
+	// caller polling Status() mid-import sees live progress rather than a
+	// jump from zero straight to the final counts once run finishes.
+	processed atomic.Int64
+	failed    atomic.Int64
+
+	resultMu sync.Mutex
+	result   []emailDomain
+}
+
+// NewImporter creates an Importer that reads emailFieldName from each CSV it
+// is asked to import, buffering at most queueSize rows in memory at once.
+func NewImporter(emailFieldName string, queueSize int) (*Importer, error) {
+	if emailFieldName == "" {
+		return nil, fmt.Errorf("the email field name is empty")
+	}
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+
+	return &Importer{
+		emailFieldName: emailFieldName,
+		queueSize:      queueSize,
+		status:         ImportStatus{State: StateNone},
+	}, nil
+}
+
+// NewSession creates an Importer that shares this customerImporter's email
+// field, so a caller can move from the one-shot GetDomainCounts to an
+// observable, cancellable import of a larger ZIP/CSV source.
+func (importer *customerImporter) NewSession(queueSize int) (*Importer, error) {
+	return NewImporter(importer.emailFieldName, queueSize)
+}
+
+// Start begins importing source in the background and returns immediately.
+// source may be a plain CSV file or a ZIP archive containing one or more
+// CSV files. It is an error to call Start while an import is already
+// in progress.
+func (importer *Importer) Start(source string) error {
+	importer.mu.Lock()
+	if importer.status.State == StateImporting || importer.status.State == StateStopping {
+		importer.mu.Unlock()
+		return fmt.Errorf("an import is already in progress")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	importer.cancel = cancel
+	importer.done = make(chan struct{})
+	importer.status = ImportStatus{State: StateImporting, StartedAt: time.Now()}
+	importer.processed.Store(0)
+	importer.failed.Store(0)
+	importer.mu.Unlock()
+
+	go importer.run(ctx, source)
+
+	return nil
+}
+
+// Stop requests that an in-progress import stop as soon as possible and
+// blocks until it has. It is a no-op if no import is running.
+func (importer *Importer) Stop() {
+	importer.mu.Lock()
+	if importer.status.State == StateStopping {
+		done := importer.done
+		importer.mu.Unlock()
+		<-done
+		return
+	}
+	if importer.status.State != StateImporting {
+		importer.mu.Unlock()
+		return
+	}
+	importer.status.State = StateStopping
+	cancel := importer.cancel
+	done := importer.done
+	importer.mu.Unlock()
+
+	cancel()
+	<-done
+}
+
+// Status returns a snapshot of the current import's progress. Processed
+// and Failed are read live off atomic counters, so a caller polling Status
+// during a long-running import sees progress as it happens rather than a
+// jump straight to the final counts once the import finishes.
+func (importer *Importer) Status() ImportStatus {
+	importer.mu.Lock()
+	status := importer.status
+	importer.mu.Unlock()
+
+	status.Processed = int(importer.processed.Load())
+	status.Failed = int(importer.failed.Load())
+
+	return status
+}
+
+// Result returns the domain counts produced by the most recently finished
+// import, or nil if none has finished yet.
+func (importer *Importer) Result() []emailDomain {
+	importer.resultMu.Lock()
+	defer importer.resultMu.Unlock()
+	return importer.result
+}
+
+func (importer *Importer) run(ctx context.Context, source string) {
+	counts := make(map[string]int)
+
+	err := importer.forEachRow(ctx, source, func(record []string, emailIndex int) {
+		email := record[emailIndex]
+		if !isValidEmail(email) {
+			importer.failed.Add(1)
+			return
+		}
+
+		domain, derr := extractEmailDomain(email)
+		if derr != nil {
+			importer.failed.Add(1)
+			return
+		}
+
+		counts[domain]++
+		importer.processed.Add(1)
+	})
+
+	importer.resultMu.Lock()
+	importer.result = sortEmailDomainsByCount(counts)
+	importer.resultMu.Unlock()
+
+	importer.mu.Lock()
+	defer importer.mu.Unlock()
+	importer.status.FinishedAt = time.Now()
+	if err != nil && err != context.Canceled {
+		importer.status.State = StateFailed
+	} else {
+		importer.status.State = StateFinished
+	}
+	close(importer.done)
+}
+
+// csvSource is a single CSV stream within an import, either a plain file or
+// one entry of a ZIP archive. It is opened lazily by streamCSVEntry so that
+// a ZIP with many members never holds more than one member's reader open at
+// once, and a cancelled or failed import never leaks an opened-but-unread
+// entry.
+type csvSource struct {
+	name string
+	open func() (io.ReadCloser, error)
+}
+
+// csvRow is one validated-header row read off a csvSource, queued for the
+// caller's callback.
+type csvRow struct {
+	record     []string
+	emailIndex int
+}
+
+// forEachRow streams source (a CSV file or a ZIP of CSV files) through fn,
+// one row at a time, without holding the whole file in memory. Rows are
+// queued on a bounded channel so a slow or cancelled consumer applies
+// backpressure to the reader instead of the queue growing unbounded.
+func (importer *Importer) forEachRow(ctx context.Context, source string, fn func(record []string, emailIndex int)) error {
+	entries, closeEntries, err := openCSVEntries(source)
+	if err != nil {
+		return err
+	}
+	defer closeEntries()
+
+	rows := make(chan csvRow, importer.queueSize)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(rows)
+		for _, entry := range entries {
+			if err := streamCSVEntry(entry, importer.emailFieldName, rows, ctx.Done()); err != nil {
+				errCh <- err
+				return
+			}
+		}
+	}()
+
+	for row := range rows {
+		fn(row.record, row.emailIndex)
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+// openCSVEntries opens source and returns the CSV streams it contains: a
+// single entry for a plain CSV file, or one entry per *.csv member of a ZIP
+// archive. Members are described, not opened, here; streamCSVEntry opens
+// (and closes) each one lazily as it is actually read.
+func openCSVEntries(source string) ([]csvSource, func(), error) {
+	if looksLikeZip(source) {
+		zr, err := zip.OpenReader(source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open zip archive %s: %w", source, err)
+		}
+
+		var entries []csvSource
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(f.Name), ".csv") {
+				continue
+			}
+
+			f := f
+			entries = append(entries, csvSource{name: f.Name, open: func() (io.ReadCloser, error) { return f.Open() }})
+		}
+
+		return entries, func() { zr.Close() }, nil
+	}
+
+	return []csvSource{{name: source, open: func() (io.ReadCloser, error) { return os.Open(source) }}}, func() {}, nil
+}
+
+// looksLikeZip reports whether source is a ZIP archive, by extension first
+// and by magic number if that is inconclusive.
+func looksLikeZip(source string) bool {
+	if strings.EqualFold(filepath.Ext(source), ".zip") {
+		return true
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false
+	}
+
+	return string(magic) == "PK\x03\x04"
+}
+
+// streamCSVEntry opens entry and reads its header and rows, pushing each row
+// onto rows until the entry is exhausted or done is closed.
+func streamCSVEntry(entry csvSource, emailFieldName string, rows chan<- csvRow, done <-chan struct{}) error {
+	rc, err := entry.open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", entry.name, err)
+	}
+	defer rc.Close()
+
+	reader := csv.NewReader(rc)
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read the headers row from %s: %w", entry.name, err)
+	}
+
+	emailIndex := indexOf(header, emailFieldName)
+	if emailIndex == -1 {
+		return fmt.Errorf("failed to find the field '%s' in the headers of %s", emailFieldName, entry.name)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		select {
+		case rows <- csvRow{record: record, emailIndex: emailIndex}:
+		case <-done:
+			return context.Canceled
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package customerimporter
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTempCSV(t *testing.T, pattern, contents string) string {
+	t.Helper()
+
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+
+	return file.Name()
+}
+
+func waitForStatus(t *testing.T, importer *Importer, want ImportState) ImportStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status := importer.Status()
+		if status.State == want {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for state %s, last status: %+v", want, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestImporter_StartPlainCSV(t *testing.T) {
+	path := writeTempCSV(t, "session_plain*.csv",
+		"first_name,last_name,email,gender,ip_address\n"+
+			"Mildred,Hernandez,bortiz2@example.com,Female,38.194.51.128\n"+
+			"Bonnie,Ortiz,bortiz1@example.com,Female,197.54.209.129\n")
+	defer os.Remove(path)
+
+	importer, err := NewImporter("email", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importer.Start(path); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status := waitForStatus(t, importer, StateFinished)
+	if status.Processed != 2 || status.Failed != 0 {
+		t.Errorf("expected 2 processed, 0 failed, got %+v", status)
+	}
+
+	expected := []emailDomain{{"example.com", 2}}
+	if !isEqual(importer.Result(), expected) {
+		t.Errorf("expected %v, got %v", expected, importer.Result())
+	}
+}
+
+func TestImporter_StartZIPWithMultipleCSVs(t *testing.T) {
+	archive, err := os.CreateTemp("", "session_zip*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(archive.Name())
+
+	zw := zip.NewWriter(archive)
+	for name, contents := range map[string]string{
+		"customers_a.csv": "first_name,last_name,email,gender,ip_address\nA,A,a@example.com,Female,1.1.1.1\n",
+		"customers_b.csv": "first_name,last_name,email,gender,ip_address\nB,B,b@example.com,Male,2.2.2.2\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+
+	importer, err := NewImporter("email", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importer.Start(archive.Name()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status := waitForStatus(t, importer, StateFinished)
+	if status.Processed != 2 {
+		t.Errorf("expected 2 processed rows across both CSV entries, got %+v", status)
+	}
+}
+
+func TestImporter_StartWhileRunning(t *testing.T) {
+	path := writeTempCSV(t, "session_busy*.csv", "first_name,email\nA,a@example.com\n")
+	defer os.Remove(path)
+
+	importer, err := NewImporter("email", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importer.Start(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importer.Start(path); err == nil {
+		t.Error("expected an error starting a second import while one is in progress")
+	}
+
+	waitForStatus(t, importer, StateFinished)
+}
+
+func TestImporter_Stop(t *testing.T) {
+	path := writeTempCSV(t, "session_stop*.csv", "first_name,email\nA,a@example.com\nB,b@example.com\n")
+	defer os.Remove(path)
+
+	importer, err := NewImporter("email", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importer.Start(path); err != nil {
+		t.Fatal(err)
+	}
+
+	importer.Stop()
+
+	status := importer.Status()
+	if status.State != StateFinished && status.State != StateFailed {
+		t.Errorf("expected import to have stopped, got state %s", status.State)
+	}
+	if status.FinishedAt.IsZero() {
+		t.Error("expected FinishedAt to be set after Stop")
+	}
+}
+
+func TestNewImporter_EmptyEmailField(t *testing.T) {
+	if _, err := NewImporter("", 0); err == nil {
+		t.Error("expected error for empty email field name, got nil")
+	}
+}
+
+func TestImporter_StatusObservableMidImport(t *testing.T) {
+	var body strings.Builder
+	body.WriteString("first_name,email\n")
+	const rows = 5000
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&body, "User%d,user%d@example.com\n", i, i)
+	}
+	path := writeTempCSV(t, "session_progress*.csv", body.String())
+	defer os.Remove(path)
+
+	// A queue of 1 forces the producer to wait on each consumer, giving the
+	// poll loop below a real chance to observe an in-flight status.
+	importer, err := NewImporter("email", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importer.Start(path); err != nil {
+		t.Fatal(err)
+	}
+
+	sawMidImport := false
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		status := importer.Status()
+		if status.State == StateImporting && status.Processed > 0 && status.Processed < rows {
+			sawMidImport = true
+			break
+		}
+		if status.State == StateFinished || status.State == StateFailed {
+			break
+		}
+	}
+
+	waitForStatus(t, importer, StateFinished)
+
+	if !sawMidImport {
+		t.Error("expected to observe Processed increase before the import finished, but it only ever jumped straight to the final count")
+	}
+
+	final := importer.Status()
+	if final.Processed != rows {
+		t.Errorf("expected %d processed rows at completion, got %d", rows, final.Processed)
+	}
+}
@@ -0,0 +1,167 @@
+package customerimporter
+
+// A DomainValidator adds a deliverability check on top of the syntactic
+// validation in isValidEmail: a domain can parse as a valid address and
+// still not resolve to anything (typos, dead TLDs, etc.), which the counts
+// from GetDomainCounts alone cannot express.
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// DomainValidator checks whether email domains have at least one MX record,
+// memoizing the result per domain and bounding how many lookups run at
+// once. It is safe for concurrent use.
+type DomainValidator struct {
+	timeout time.Duration
+	workers int
+	lookup  func(ctx context.Context, domain string) ([]*net.MX, error)
+
+	mu    sync.Mutex
+	cache map[string]bool
+}
+
+// NewDomainValidator creates a DomainValidator that looks up MX records
+// with the given per-domain timeout, running up to workers lookups
+// concurrently. A non-positive timeout or workers count falls back to
+// 5 seconds and 8 workers respectively.
+func NewDomainValidator(timeout time.Duration, workers int) *DomainValidator {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if workers <= 0 {
+		workers = 8
+	}
+
+	return &DomainValidator{
+		timeout: timeout,
+		workers: workers,
+		lookup:  net.DefaultResolver.LookupMX,
+		cache:   make(map[string]bool),
+	}
+}
+
+// HasMX reports whether domain has at least one MX record, memoizing the
+// result so a repeated domain is served from the cache once its first
+// lookup has finished. Concurrent calls for a domain that is not cached yet
+// each perform their own lookup.
+func (validator *DomainValidator) HasMX(ctx context.Context, domain string) bool {
+	validator.mu.Lock()
+	if cached, ok := validator.cache[domain]; ok {
+		validator.mu.Unlock()
+		return cached
+	}
+	validator.mu.Unlock()
+
+	lookupCtx, cancel := context.WithTimeout(ctx, validator.timeout)
+	defer cancel()
+
+	records, err := validator.lookup(lookupCtx, domain)
+	hasMX := err == nil && len(records) > 0
+
+	validator.mu.Lock()
+	validator.cache[domain] = hasMX
+	validator.mu.Unlock()
+
+	return hasMX
+}
+
+// partition checks every domain with a bounded pool of validator.workers
+// workers and splits them into the ones with a usable MX record and the
+// ones without.
+func (validator *DomainValidator) partition(ctx context.Context, domains []string) (valid, unreachable []string) {
+	type result struct {
+		domain string
+		hasMX  bool
+	}
+
+	jobs := make(chan string)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < validator.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for domain := range jobs {
+				results <- result{domain: domain, hasMX: validator.HasMX(ctx, domain)}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, domain := range domains {
+			jobs <- domain
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		if r.hasMX {
+			valid = append(valid, r.domain)
+		} else {
+			unreachable = append(unreachable, r.domain)
+		}
+	}
+
+	return valid, unreachable
+}
+
+// DomainCountsResult separates the syntactically valid domains counted by
+// GetDomainCounts into ones that are actually deliverable (Valid) and ones
+// that are not (Unreachable).
+type DomainCountsResult struct {
+	Valid       []emailDomain
+	Unreachable []emailDomain
+}
+
+// EnableDomainValidation turns on MX-record verification for
+// GetValidatedDomainCounts.
+func (importer *customerImporter) EnableDomainValidation(validator *DomainValidator) {
+	importer.domainValidator = validator
+}
+
+// GetValidatedDomainCounts behaves like GetDomainCounts, additionally
+// splitting the result into Valid and Unreachable buckets when a
+// DomainValidator has been set via EnableDomainValidation. Without one,
+// every domain is reported as Valid.
+func (importer *customerImporter) GetValidatedDomainCounts(ctx context.Context) (DomainCountsResult, error) {
+	counts, err := importer.GetDomainCounts()
+	if err != nil {
+		return DomainCountsResult{}, err
+	}
+
+	if importer.domainValidator == nil {
+		return DomainCountsResult{Valid: counts}, nil
+	}
+
+	domains := make([]string, len(counts))
+	for i, d := range counts {
+		domains[i] = d.Domain
+	}
+
+	valid, _ := importer.domainValidator.partition(ctx, domains)
+	isValid := make(map[string]struct{}, len(valid))
+	for _, domain := range valid {
+		isValid[domain] = struct{}{}
+	}
+
+	var result DomainCountsResult
+	for _, d := range counts {
+		if _, ok := isValid[d.Domain]; ok {
+			result.Valid = append(result.Valid, d)
+		} else {
+			result.Unreachable = append(result.Unreachable, d)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package customerimporter
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDomainValidator_HasMX(t *testing.T) {
+	validator := NewDomainValidator(time.Second, 2)
+	calls := 0
+	validator.lookup = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		calls++
+		if domain == "good.com" {
+			return []*net.MX{{Host: "mail.good.com."}}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: domain}
+	}
+
+	if !validator.HasMX(context.Background(), "good.com") {
+		t.Error("expected good.com to have an MX record")
+	}
+	if validator.HasMX(context.Background(), "bad.com") {
+		t.Error("expected bad.com to have no MX record")
+	}
+
+	// Second call for the same domain should be served from the cache.
+	validator.HasMX(context.Background(), "good.com")
+	if calls != 2 {
+		t.Errorf("expected 2 lookups (one per distinct domain), got %d", calls)
+	}
+}
+
+func TestDomainValidator_Partition(t *testing.T) {
+	validator := NewDomainValidator(time.Second, 4)
+	validator.lookup = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		if domain == "good.com" {
+			return []*net.MX{{Host: "mail.good.com."}}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: domain}
+	}
+
+	valid, unreachable := validator.partition(context.Background(), []string{"good.com", "bad.com", "good.com"})
+	if len(valid) != 2 || len(unreachable) != 1 {
+		t.Errorf("expected 2 valid and 1 unreachable, got valid=%v unreachable=%v", valid, unreachable)
+	}
+}
+
+func TestGetValidatedDomainCounts(t *testing.T) {
+	file, err := os.CreateTemp("", "validated_counts*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	csvContents := "first_name,email\nA,a@good.com\nB,b@bad.com\nC,c@good.com\n"
+	if _, err := file.WriteString(csvContents); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewDomainValidator(time.Second, 2)
+	validator.lookup = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		if domain == "good.com" {
+			return []*net.MX{{Host: "mail.good.com."}}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: domain}
+	}
+	importer.EnableDomainValidation(validator)
+
+	result, err := importer.GetValidatedDomainCounts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isEqual(result.Valid, []emailDomain{{"good.com", 2}}) {
+		t.Errorf("expected good.com in Valid, got %v", result.Valid)
+	}
+	if !isEqual(result.Unreachable, []emailDomain{{"bad.com", 1}}) {
+		t.Errorf("expected bad.com in Unreachable, got %v", result.Unreachable)
+	}
+}
@@ -0,0 +1,106 @@
+package customerimporter
+
+// Watch gives long-running callers (e.g. a service that keeps a
+// customerImporter around for its whole lifetime) a way to pick up changes
+// to csvFilePath without restarting: the latest domain counts are kept
+// behind an atomic.Pointer for lock-free reads, and subscribers are
+// notified whenever a new snapshot is published.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch monitors csvFilePath for writes and renames and republishes an
+// updated snapshot of domain counts each time the file changes. It blocks
+// until ctx is cancelled or the watch fails to start.
+func (importer *customerImporter) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create a file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	dir := filepath.Dir(importer.csvFilePath)
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+	}
+
+	importer.refreshSnapshot()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(importer.csvFilePath) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			importer.refreshSnapshot()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Println(err)
+		}
+	}
+}
+
+// Snapshot returns the most recently published domain counts, or nil if
+// Watch has not yet produced one.
+func (importer *customerImporter) Snapshot() []emailDomain {
+	current := importer.snapshot.Load()
+	if current == nil {
+		return nil
+	}
+	return *current
+}
+
+// Subscribe returns a channel that receives every new snapshot published by
+// Watch. The channel is buffered by one and never closed; a subscriber that
+// falls behind only ever sees the most recent snapshot.
+func (importer *customerImporter) Subscribe() <-chan []emailDomain {
+	ch := make(chan []emailDomain, 1)
+
+	importer.subMu.Lock()
+	importer.subscribers = append(importer.subscribers, ch)
+	importer.subMu.Unlock()
+
+	return ch
+}
+
+func (importer *customerImporter) refreshSnapshot() {
+	domains, err := importer.GetDomainCounts()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	importer.snapshot.Store(&domains)
+	importer.publish(domains)
+}
+
+func (importer *customerImporter) publish(domains []emailDomain) {
+	importer.subMu.Lock()
+	defer importer.subMu.Unlock()
+
+	for _, ch := range importer.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- domains
+	}
+}
@@ -0,0 +1,61 @@
+package customerimporter
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatch_PublishesSnapshotOnWrite(t *testing.T) {
+	file, err := os.CreateTemp("", "watch_test*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString("first_name,email\nA,a@example.com\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	importer, err := NewCustomerImporter("email", WithFile(file.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates := importer.Subscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go importer.Watch(ctx)
+
+	select {
+	case domains := <-updates:
+		expected := []emailDomain{{"example.com", 1}}
+		if !isEqual(domains, expected) {
+			t.Errorf("expected %v, got %v", expected, domains)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for initial snapshot")
+	}
+
+	if err := os.WriteFile(file.Name(), []byte("first_name,email\nA,a@example.com\nB,b@example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case domains := <-updates:
+		expected := []emailDomain{{"example.com", 2}}
+		if !isEqual(domains, expected) {
+			t.Errorf("expected %v, got %v", expected, domains)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for updated snapshot")
+	}
+
+	if snap := importer.Snapshot(); len(snap) != 1 || snap[0].CustomerCount != 2 {
+		t.Errorf("expected snapshot to reflect latest write, got %v", snap)
+	}
+}